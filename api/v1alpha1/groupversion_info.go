@@ -0,0 +1,29 @@
+// Package v1alpha1 contains API Schema definitions for the ipam
+// v1alpha1 API group, the schiff-operator's own IPAM-related CRDs.
+// +kubebuilder:object:generate=true
+// +groupName=ipam.schiff.telekom.de
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+const (
+	// Version is the API version.
+	Version = "v1alpha1"
+
+	// GroupName is the name of the API group.
+	GroupName = "ipam.schiff.telekom.de"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: GroupName, Version: Version}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
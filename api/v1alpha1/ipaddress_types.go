@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPAddressSpec records a single address allocated for a claim.
+type IPAddressSpec struct {
+	// ClaimRef names the IPAddressClaim this address was allocated for.
+	ClaimRef corev1.LocalObjectReference `json:"claimRef"`
+
+	// Address is the allocated IP address.
+	Address string `json:"address"`
+
+	// Prefix is the subnet prefix length the address was allocated from,
+	// e.g. 24 for a /24.
+	Prefix int `json:"prefix"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=ipaddresses,scope=Namespaced
+
+// IPAddress is an address bound to an IPAddressClaim. It's created by the
+// IPAddressClaimReconciler once the backend has allocated an address, and
+// is owned by (and deleted along with) its IPAddressClaim.
+type IPAddress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IPAddressSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IPAddressList contains a list of IPAddress.
+type IPAddressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddress{}, &IPAddressList{})
+}
@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPAddressClaimSpec requests a single address from an IPAM backend. It
+// mirrors the shape of the upstream cluster-api IPAM contract, which isn't
+// available at this repo's pinned cluster-api version.
+type IPAddressClaimSpec struct {
+	// PoolRef optionally references an IPPool to allocate from; when set,
+	// its Spec.CIDR takes precedence over Subnet below. Leave unset for
+	// backends, like Infoblox, that aren't backed by an in-cluster pool
+	// resource.
+	// +optional
+	PoolRef *corev1.TypedLocalObjectReference `json:"poolRef,omitempty"`
+
+	// Backend selects the ipam.Manager registered under this name to
+	// fulfill the claim. Empty resolves to the registry's default backend.
+	// +optional
+	Backend string `json:"backend,omitempty"`
+
+	// NetworkView is passed to backends, like Infoblox, that partition a
+	// subnet by network view rather than by an in-cluster pool resource.
+	// +optional
+	NetworkView string `json:"networkView,omitempty"`
+
+	// Subnet is the CIDR to allocate from. Required unless PoolRef is set.
+	// +optional
+	Subnet string `json:"subnet,omitempty"`
+}
+
+// IPAddressClaimStatus reports the IPAddress bound to a claim once the
+// backend has fulfilled it.
+type IPAddressClaimStatus struct {
+	// AddressRef names the IPAddress bound to this claim.
+	// +optional
+	AddressRef corev1.LocalObjectReference `json:"addressRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=ipaddressclaims,scope=Namespaced
+// +kubebuilder:subresource:status
+
+// IPAddressClaim requests an address from an IPAM backend. The
+// IPAddressClaimReconciler fulfills claims by calling the resolved
+// ipam.Manager backend and binds an IPAddress once allocated; deleting a
+// claim releases its address back to the backend.
+type IPAddressClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPAddressClaimSpec   `json:"spec,omitempty"`
+	Status IPAddressClaimStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IPAddressClaimList contains a list of IPAddressClaim.
+type IPAddressClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPAddressClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPAddressClaim{}, &IPAddressClaimList{})
+}
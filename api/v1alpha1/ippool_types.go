@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPPoolAllocation records a single address handed out from an IPPool,
+// keyed by the IPAM identifier it was allocated for.
+type IPPoolAllocation struct {
+	// Identifier is the IPAM identifier the address was allocated for, e.g.
+	// a VSphereMachine device identifier.
+	Identifier string `json:"identifier"`
+
+	// Address is the allocated IP address.
+	Address string `json:"address"`
+}
+
+// IPPoolSpec defines the CIDR a kube-native IPAM backend allocates
+// addresses from.
+type IPPoolSpec struct {
+	// CIDR is the subnet addresses are allocated from, e.g. "10.0.0.0/16".
+	CIDR string `json:"cidr"`
+
+	// Reserved lists addresses (single IPs or CIDRs) within CIDR that must
+	// never be handed out, e.g. gateways or an existing DHCP range.
+	// +optional
+	Reserved []string `json:"reserved,omitempty"`
+}
+
+// IPPoolStatus records the addresses currently allocated from an IPPool.
+type IPPoolStatus struct {
+	// Allocations lists the addresses currently handed out from CIDR.
+	// +optional
+	Allocations []IPPoolAllocation `json:"allocations,omitempty"`
+
+	// NextOffset is the offset from the start of CIDR the allocator will
+	// next consider, so a long-lived pool doesn't have to rescan addresses
+	// it already knows are in use. The allocator's scan wraps around to the
+	// start of CIDR after its last address.
+	// +optional
+	NextOffset int64 `json:"nextOffset,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=ippools,scope=Namespaced
+// +kubebuilder:subresource:status
+
+// IPPool is a kube-native IPAM pool: a CIDR plus the addresses currently
+// allocated from it. It backs the pkg/ipam/kubenative Manager, so clusters
+// without an external IPAM system such as Infoblox can still use the
+// VSphereMachine IPAM controller.
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec,omitempty"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IPPoolList contains a list of IPPool.
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPPool{}, &IPPoolList{})
+}
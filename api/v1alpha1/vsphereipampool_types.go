@@ -0,0 +1,85 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FailureDomainPool configures the IPAM subnet and network view that
+// VSphereMachines in a given failure domain should allocate addresses from.
+type FailureDomainPool struct {
+	// Name is the failure domain this entry applies to. It must match the
+	// failure domain name set on the owning Machine's Spec.FailureDomain.
+	Name string `json:"name"`
+
+	// NetworkName is the vSphere network the subnet below belongs to. An
+	// entry only applies to devices connected to this network.
+	NetworkName string `json:"networkName"`
+
+	// NetworkView is the Infoblox network view to allocate from.
+	// +optional
+	NetworkView string `json:"networkView,omitempty"`
+
+	// Subnet is the CIDR to allocate addresses from, e.g. "10.0.0.0/24".
+	Subnet string `json:"subnet"`
+}
+
+// VSphereIPAMPoolSpec defines the per-failure-domain IPAM configuration
+// available to VSphereMachines in the pool's namespace.
+type VSphereIPAMPoolSpec struct {
+	// FailureDomains lists the failure domains this pool provides IPAM
+	// configuration for.
+	FailureDomains []FailureDomainPool `json:"failureDomains"`
+}
+
+// FailureDomainPoolStatus reports the observed IP utilization of a single
+// FailureDomainPool entry.
+type FailureDomainPoolStatus struct {
+	// Name is the failure domain this status applies to.
+	Name string `json:"name"`
+
+	// Subnet is the CIDR this status was computed from.
+	Subnet string `json:"subnet"`
+
+	// AllocatedIPs is the number of addresses currently allocated from
+	// Subnet by VSphereMachines in this failure domain.
+	AllocatedIPs int `json:"allocatedIPs"`
+
+	// TotalIPs is the size of Subnet, counting every address in it
+	// including the network and broadcast addresses.
+	TotalIPs int `json:"totalIPs"`
+}
+
+// VSphereIPAMPoolStatus records per-failure-domain allocation utilization so
+// operators can see which failure domain is running low on addresses.
+type VSphereIPAMPoolStatus struct {
+	// FailureDomains reports utilization for each configured failure domain.
+	// +optional
+	FailureDomains []FailureDomainPoolStatus `json:"failureDomains,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=vsphereipampools,scope=Namespaced
+// +kubebuilder:subresource:status
+
+// VSphereIPAMPool maps failure domain names to the subnet and network view
+// that VSphereMachines in that failure domain should allocate IPs from.
+type VSphereIPAMPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VSphereIPAMPoolSpec   `json:"spec,omitempty"`
+	Status VSphereIPAMPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VSphereIPAMPoolList contains a list of VSphereIPAMPool.
+type VSphereIPAMPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VSphereIPAMPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VSphereIPAMPool{}, &VSphereIPAMPoolList{})
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam"
+)
+
+// ipAddressClaimFinalizer is added to an IPAddressClaim before an address
+// is allocated for it, so we get a chance to release that address back to
+// the backend on delete.
+const ipAddressClaimFinalizer = "ipaddressclaim.schiff.telekom.de/ipam"
+
+// IPAddressClaimReconciler fulfills IPAddressClaims by calling the
+// resolved ipam.Manager backend and binding an IPAddress once allocated.
+// It's the kube-native half of the CAPI-IPAM-shaped contract consumed by
+// VSphereMachineReconciler: that controller creates claims and waits for
+// them to bind rather than calling a backend directly.
+type IPAddressClaimReconciler struct {
+	client.Client
+	Log          logr.Logger
+	Scheme       *runtime.Scheme
+	IPAMBackends *ipam.Registry
+}
+
+func (r *IPAddressClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("ipaddressclaim", req.NamespacedName)
+
+	claim := &ipamv1alpha1.IPAddressClaim{}
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !claim.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, log, claim)
+	}
+
+	if !controllerutil.ContainsFinalizer(claim, ipAddressClaimFinalizer) {
+		controllerutil.AddFinalizer(claim, ipAddressClaimFinalizer)
+		return ctrl.Result{}, r.Update(ctx, claim)
+	}
+
+	if claim.Status.AddressRef.Name != "" {
+		return ctrl.Result{}, nil
+	}
+
+	manager, err := r.IPAMBackends.Get(claim.Spec.Backend)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("resolving IPAM backend for claim %s: %w", claim.Name, err)
+	}
+
+	subnet, err := r.subnetFor(ctx, claim)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ip, err := manager.GetOrAllocateIP(claim.Name, claim.Spec.NetworkView, subnet)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("allocating IP for claim %s: %w", claim.Name, err)
+	}
+
+	ones, _ := subnet.Mask.Size()
+	address := &ipamv1alpha1.IPAddress{
+		ObjectMeta: metav1.ObjectMeta{Name: claim.Name, Namespace: claim.Namespace},
+		Spec: ipamv1alpha1.IPAddressSpec{
+			ClaimRef: corev1.LocalObjectReference{Name: claim.Name},
+			Address:  ip.String(),
+			Prefix:   ones,
+		},
+	}
+	if err := controllerutil.SetControllerReference(claim, address, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, address); err != nil {
+		return ctrl.Result{}, fmt.Errorf("creating IPAddress for claim %s: %w", claim.Name, err)
+	}
+
+	claim.Status.AddressRef.Name = address.Name
+	if err := r.Status().Update(ctx, claim); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *IPAddressClaimReconciler) reconcileDelete(ctx context.Context, log logr.Logger, claim *ipamv1alpha1.IPAddressClaim) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(claim, ipAddressClaimFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if claim.Status.AddressRef.Name != "" {
+		subnet, err := r.subnetFor(ctx, claim)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		manager, err := r.IPAMBackends.Get(claim.Spec.Backend)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("resolving IPAM backend for claim %s: %w", claim.Name, err)
+		}
+
+		if err := manager.ReleaseIP(claim.Name, claim.Spec.NetworkView, subnet); err != nil {
+			return ctrl.Result{}, fmt.Errorf("releasing IP for claim %s: %w", claim.Name, err)
+		}
+
+		address := &ipamv1alpha1.IPAddress{ObjectMeta: metav1.ObjectMeta{Name: claim.Name, Namespace: claim.Namespace}}
+		if err := r.Delete(ctx, address); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(claim, ipAddressClaimFinalizer)
+	if err := r.Update(ctx, claim); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("released IP address claim")
+	return ctrl.Result{}, nil
+}
+
+// subnetFor resolves the CIDR claim should allocate from: the referenced
+// IPPool's CIDR when PoolRef is set, otherwise claim.Spec.Subnet.
+func (r *IPAddressClaimReconciler) subnetFor(ctx context.Context, claim *ipamv1alpha1.IPAddressClaim) (*net.IPNet, error) {
+	cidr := claim.Spec.Subnet
+	if claim.Spec.PoolRef != nil {
+		pool := &ipamv1alpha1.IPPool{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: claim.Namespace, Name: claim.Spec.PoolRef.Name}, pool); err != nil {
+			return nil, fmt.Errorf("resolving poolRef for claim %s: %w", claim.Name, err)
+		}
+		cidr = pool.Spec.CIDR
+	}
+
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing subnet for claim %s: %w", claim.Name, err)
+	}
+	return subnet, nil
+}
+
+func (r *IPAddressClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ipamv1alpha1.IPAddressClaim{}).
+		Owns(&ipamv1alpha1.IPAddress{}).
+		Complete(r)
+}
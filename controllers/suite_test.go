@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha3"
+	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam"
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam/mock"
+)
+
+const (
+	timeout  = time.Second * 10
+	duration = time.Second * 2
+	interval = time.Millisecond * 250
+)
+
+var (
+	k8sClient   client.Client
+	testEnv     *envtest.Environment
+	ipamManager = &mock.Manager{}
+)
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecsWithDefaultAndCustomReporters(t, "Controller Suite", []Reporter{})
+}
+
+var _ = BeforeSuite(func(done Done) {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+	}
+
+	cfg, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(cfg).NotTo(BeNil())
+
+	Expect(v1alpha3.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(capiv1alpha3.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(ipamv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme})
+	Expect(err).NotTo(HaveOccurred())
+
+	backends := ipam.NewRegistry(map[string]ipam.Manager{ipam.DefaultBackend: ipamManager}, ipam.DefaultBackend)
+
+	Expect((&VSphereMachineReconciler{
+		Client:       k8sManager.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("VSphereMachine"),
+		Scheme:       k8sManager.GetScheme(),
+		IPAMBackends: backends,
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	Expect((&IPAddressClaimReconciler{
+		Client:       k8sManager.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("IPAddressClaim"),
+		Scheme:       k8sManager.GetScheme(),
+		IPAMBackends: backends,
+	}).SetupWithManager(k8sManager)).To(Succeed())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(k8sManager.Start(ctrl.SetupSignalHandler())).To(Succeed())
+	}()
+
+	k8sClient = k8sManager.GetClient()
+	Expect(k8sClient).NotTo(BeNil())
+
+	close(done)
+}, 60)
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environment")
+	Expect(testEnv.Stop()).To(Succeed())
+})
@@ -0,0 +1,691 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api-provider-vsphere/api/v1alpha3"
+	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam"
+)
+
+const (
+	// clusterNameLabel mirrors the Machine's cluster onto the VSphereMachine
+	// so it can be matched back without an extra lookup.
+	clusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+	// infobloxNetworkViewAnnotation and subnetAnnotation configure IPAM
+	// allocation for a VSphereMachine's network devices; networkNameAnnotation
+	// is not read by this controller, which keys devices by their own
+	// NetworkName. They
+	// are read from the VSphereMachine itself, falling back to the owning
+	// Machine when the VSphereMachine doesn't carry them (e.g. when they're
+	// set once on the Machine template). For machines with more than one
+	// device, these are suffixed per-device with ".<networkName>"; the
+	// un-suffixed form is only ever consulted for the first device, so
+	// existing single-NIC machines keep working unchanged.
+	networkNameAnnotation         = "schiff.telekom.de/network-name"
+	infobloxNetworkViewAnnotation = "schiff.telekom.de/network-view"
+	subnetAnnotation              = "schiff.telekom.de/subnet"
+
+	// assignmentTypeAnnotation declares how a device's address is assigned:
+	// "ipam" (allocate via an IPAddressClaim), "dhcp" (DHCP is authoritative, we
+	// don't touch IPAddrs) or "static" (IPAddrs is already set and must be
+	// left alone). Like the annotations above it may be suffixed per-device
+	// with ".<networkName>"; the un-suffixed form is only consulted for the
+	// first device. When absent entirely, it defaults to "dhcp" if the
+	// device has DHCP4 set and "ipam" otherwise, preserving the controller's
+	// original behavior.
+	assignmentTypeAnnotation = "schiff.telekom.de/assignment-type"
+
+	// ipamBackendAnnotation selects which registered ipam.Manager backend
+	// (e.g. "infoblox" or "kubenative") allocates a device's address. Like
+	// the annotations above it may be suffixed per-device with
+	// ".<networkName>"; the un-suffixed form is only consulted for the
+	// first device. When absent, IPAMBackends resolves its configured
+	// default backend.
+	ipamBackendAnnotation = "schiff.telekom.de/ipam-backend"
+
+	// finalizer is added to a VSphereMachine before any IP is allocated for
+	// it, as soon as one of its IPAM devices has no address yet, so we get a
+	// chance to release its IPs again on delete.
+	finalizer = "vspheremachine.schiff.telekom.de/ipam"
+
+	// releasedDevicesAnnotation records, as a comma-separated list of
+	// device indices, which of a terminating machine's IPAM devices have
+	// already had their IPAddressClaim deleted by reconcileDelete. Once
+	// that claim fully terminates it disappears from etcd just like a
+	// device that never had a claim at all; this annotation is the only
+	// way to tell those two cases apart, so a device's release is never
+	// performed twice.
+	releasedDevicesAnnotation = "schiff.telekom.de/ipam-released-devices"
+)
+
+// assignmentType is the value of assignmentTypeAnnotation for a device.
+type assignmentType string
+
+const (
+	assignmentTypeIPAM   assignmentType = "ipam"
+	assignmentTypeDHCP   assignmentType = "dhcp"
+	assignmentTypeStatic assignmentType = "static"
+)
+
+// DHCPAuthoritativeCondition is set on a VSphereMachine once at least one of
+// its devices has assignment type "dhcp", recording that DHCP - not this
+// controller - owns that device's address.
+const DHCPAuthoritativeCondition capiv1alpha3.ConditionType = "DHCPAuthoritative"
+
+// VSphereMachineReconciler allocates and releases IP addresses for
+// VSphereMachine network devices via the ipam.Manager backends registered
+// in IPAMBackends.
+type VSphereMachineReconciler struct {
+	client.Client
+	Log          logr.Logger
+	Scheme       *runtime.Scheme
+	IPAMBackends *ipam.Registry
+}
+
+func (r *VSphereMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("vspheremachine", req.NamespacedName)
+
+	machine := &v1alpha3.VSphereMachine{}
+	if err := r.Get(ctx, req.NamespacedName, machine); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if len(machine.Spec.Network.Devices) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	annotations, err := r.annotationsFor(ctx, machine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	failureDomain, err := r.failureDomainFor(ctx, machine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !machine.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, log, machine, annotations, failureDomain)
+	}
+
+	return r.reconcileNormal(ctx, log, machine, annotations, failureDomain)
+}
+
+// reconcileNormal ensures every IPAM-assigned device has a bound
+// IPAddressClaim, copying its address onto the device once bound.
+//
+// Note: earlier versions of this controller allocated IPs directly and
+// rolled back already-allocated devices if a later device in the same
+// reconcile failed. Since the move to IPAddressClaim, each device's
+// allocation is fulfilled independently and asynchronously by
+// IPAddressClaimReconciler, so a later device failing to bind no longer
+// rolls back an earlier device's already-bound claim - that's the point of
+// decoupling allocation from this controller's single reconcile loop. See
+// the "keeps the IP allocated to an earlier device..." test in
+// vspheremachine_ipam_controller_test.go.
+func (r *VSphereMachineReconciler) reconcileNormal(ctx context.Context, log logr.Logger, machine *v1alpha3.VSphereMachine, annotations map[string]string, failureDomain string) (ctrl.Result, error) {
+	devices := machine.Spec.Network.Devices
+
+	needsIPAM := false
+	for i := range devices {
+		if deviceAssignmentType(annotations, &devices[i], i) == assignmentTypeIPAM && len(devices[i].IPAddrs) == 0 {
+			needsIPAM = true
+			break
+		}
+	}
+
+	if needsIPAM && !controllerutil.ContainsFinalizer(machine, finalizer) {
+		controllerutil.AddFinalizer(machine, finalizer)
+		if err := r.Update(ctx, machine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	updated := false
+	dhcpAuthoritative := false
+	for i := range devices {
+		dev := &devices[i]
+
+		switch deviceAssignmentType(annotations, dev, i) {
+		case assignmentTypeDHCP:
+			dhcpAuthoritative = true
+			continue
+		case assignmentTypeStatic:
+			if len(dev.IPAddrs) == 0 {
+				return ctrl.Result{}, fmt.Errorf("device %d (%s) has assignment type %q but no IPAddrs set", i, dev.NetworkName, assignmentTypeStatic)
+			}
+			continue
+		case assignmentTypeIPAM:
+			// handled below
+		default:
+			return ctrl.Result{}, fmt.Errorf("device %d (%s) has unknown assignment type %q", i, dev.NetworkName, deviceAssignmentType(annotations, dev, i))
+		}
+
+		if len(dev.IPAddrs) > 0 {
+			continue
+		}
+
+		cfg, pool, err := r.resolveDeviceConfig(ctx, machine, dev, i, annotations, failureDomain)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		identifier := deviceIdentifier(machine.Name, i)
+		claim, err := r.ensureIPAddressClaim(ctx, machine, identifier, cfg, deviceBackendName(annotations, dev, i))
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("ensuring IPAddressClaim for device %d (%s): %w", i, dev.NetworkName, err)
+		}
+		if claim.Status.AddressRef.Name == "" {
+			// Not bound yet; the claim's own controller will fulfill it and
+			// the resulting status update re-triggers this reconcile.
+			continue
+		}
+
+		address := &ipamv1alpha1.IPAddress{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: claim.Status.AddressRef.Name}, address); err != nil {
+			return ctrl.Result{}, fmt.Errorf("reading bound IPAddress for device %d (%s): %w", i, dev.NetworkName, err)
+		}
+
+		if pool != nil {
+			poolKey := types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name}
+			if err := r.recordPoolUtilization(ctx, poolKey, failureDomain, 1); err != nil {
+				log.Error(err, "failed to record pool utilization", "pool", pool.Name)
+			}
+		}
+
+		dev.IPAddrs = []string{fmt.Sprintf("%s/%d", address.Spec.Address, address.Spec.Prefix)}
+		updated = true
+	}
+
+	if dhcpAuthoritative && !hasTrueCondition(machine, DHCPAuthoritativeCondition) {
+		markConditionTrue(machine, DHCPAuthoritativeCondition)
+		if err := r.Status().Update(ctx, machine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if updated {
+		if err := r.Update(ctx, machine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *VSphereMachineReconciler) reconcileDelete(ctx context.Context, log logr.Logger, machine *v1alpha3.VSphereMachine, annotations map[string]string, failureDomain string) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(machine, finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	released := releasedDevices(machine)
+	newlyReleased := false
+	claimsPending := false
+
+	for i, dev := range machine.Spec.Network.Devices {
+		if deviceAssignmentType(annotations, &dev, i) != assignmentTypeIPAM {
+			continue
+		}
+		identifier := deviceIdentifier(machine.Name, i)
+
+		claim := &ipamv1alpha1.IPAddressClaim{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: identifier}, claim)
+		switch {
+		case err == nil:
+			// Devices allocated through an IPAddressClaim are released by
+			// that claim's own controller once the claim is actually
+			// deleted. We delete it explicitly here rather than relying on
+			// owner-reference garbage collection: GC is driven by
+			// kube-controller-manager, which envtest doesn't run, so it
+			// would never happen in tests and isn't guaranteed to run
+			// promptly elsewhere either. We record the pool utilization
+			// decrement in the same step, since that's this controller's
+			// job (the claim reconciler doesn't know about VSphereIPAMPool),
+			// guarded on DeletionTimestamp so it only fires once and on the
+			// claim having actually bound, to mirror the condition
+			// reconcileNormal increments under - an unbound claim was
+			// never counted as allocated. We keep our own finalizer until
+			// the claim is gone - Owns() below re-triggers this reconcile
+			// as the claim progresses towards deletion.
+			if claim.DeletionTimestamp.IsZero() {
+				if claim.Status.AddressRef.Name != "" {
+					if _, pool, err := r.resolveDeviceConfig(ctx, machine, &dev, i, annotations, failureDomain); err != nil {
+						log.Error(err, "skipping utilization decrement, no IPAM config for device", "device", i)
+					} else if pool != nil {
+						poolKey := types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name}
+						if err := r.recordPoolUtilization(ctx, poolKey, failureDomain, -1); err != nil {
+							log.Error(err, "failed to record pool utilization", "pool", pool.Name)
+						}
+					}
+				}
+				if err := r.Delete(ctx, claim); err != nil && !apierrors.IsNotFound(err) {
+					return ctrl.Result{}, fmt.Errorf("deleting IPAddressClaim for device %d (%s): %w", i, dev.NetworkName, err)
+				}
+				if !released[i] {
+					released[i] = true
+					newlyReleased = true
+				}
+			}
+			claimsPending = true
+		case apierrors.IsNotFound(err):
+			if released[i] {
+				// Already released through the claim path above; the claim
+				// has since fully terminated and disappeared from etcd.
+				// Without releasedDevicesAnnotation this would be
+				// indistinguishable from a device that never had a claim,
+				// and releasing it again here would double-release it and
+				// double-decrement pool utilization.
+				continue
+			}
+
+			// No claim was ever created for this device: it was allocated
+			// before the claim-based flow existed, so release it directly.
+			// This keeps those machines working unmodified until they're
+			// recreated.
+			manager, err := r.IPAMBackends.Get(deviceBackendName(annotations, &dev, i))
+			if err != nil {
+				log.Error(err, "skipping release, no IPAM backend for device", "device", i)
+				continue
+			}
+			cfg, pool, err := r.resolveDeviceConfig(ctx, machine, &dev, i, annotations, failureDomain)
+			if err != nil {
+				log.Error(err, "skipping release, no IPAM config for device", "device", i)
+				continue
+			}
+			if err := manager.ReleaseIP(identifier, cfg.networkView, cfg.subnet); err != nil {
+				return ctrl.Result{}, fmt.Errorf("releasing IP for device %d (%s): %w", i, dev.NetworkName, err)
+			}
+			if pool != nil {
+				poolKey := types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name}
+				if err := r.recordPoolUtilization(ctx, poolKey, failureDomain, -1); err != nil {
+					log.Error(err, "failed to record pool utilization", "pool", pool.Name)
+				}
+			}
+		default:
+			return ctrl.Result{}, fmt.Errorf("checking for IPAddressClaim of device %d (%s): %w", i, dev.NetworkName, err)
+		}
+	}
+
+	if newlyReleased {
+		setReleasedDevices(machine, released)
+		if err := r.Update(ctx, machine); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if claimsPending {
+		return ctrl.Result{}, nil
+	}
+
+	controllerutil.RemoveFinalizer(machine, finalizer)
+	if err := r.Update(ctx, machine); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// releasedDevices parses machine's releasedDevicesAnnotation into the set
+// of device indices it records.
+func releasedDevices(machine *v1alpha3.VSphereMachine) map[int]bool {
+	released := map[int]bool{}
+	for _, s := range strings.Split(machine.Annotations[releasedDevicesAnnotation], ",") {
+		if s == "" {
+			continue
+		}
+		if i, err := strconv.Atoi(s); err == nil {
+			released[i] = true
+		}
+	}
+	return released
+}
+
+// setReleasedDevices persists released onto machine's
+// releasedDevicesAnnotation.
+func setReleasedDevices(machine *v1alpha3.VSphereMachine, released map[int]bool) {
+	indices := make([]int, 0, len(released))
+	for i := range released {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	parts := make([]string, len(indices))
+	for j, i := range indices {
+		parts[j] = strconv.Itoa(i)
+	}
+
+	if machine.Annotations == nil {
+		machine.Annotations = map[string]string{}
+	}
+	machine.Annotations[releasedDevicesAnnotation] = strings.Join(parts, ",")
+}
+
+type deviceConfig struct {
+	networkView string
+	subnet      *net.IPNet
+}
+
+// hasTrueCondition reports whether machine already has condition t set to
+// True.
+func hasTrueCondition(machine *v1alpha3.VSphereMachine, t capiv1alpha3.ConditionType) bool {
+	for _, c := range machine.Status.Conditions {
+		if c.Type == t {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// markConditionTrue sets condition t to True on machine, updating it in
+// place if already present.
+func markConditionTrue(machine *v1alpha3.VSphereMachine, t capiv1alpha3.ConditionType) {
+	now := metav1.Now()
+	for i := range machine.Status.Conditions {
+		if machine.Status.Conditions[i].Type == t {
+			machine.Status.Conditions[i].Status = corev1.ConditionTrue
+			machine.Status.Conditions[i].LastTransitionTime = now
+			return
+		}
+	}
+	machine.Status.Conditions = append(machine.Status.Conditions, capiv1alpha3.Condition{
+		Type:               t,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: now,
+	})
+}
+
+// deviceAssignmentType resolves how device i's address is assigned, keyed by
+// network name, falling back to the un-suffixed annotation for the first
+// device and then to the implicit DHCP4-based default.
+func deviceAssignmentType(annotations map[string]string, dev *v1alpha3.NetworkDeviceSpec, i int) assignmentType {
+	if v, ok := annotations[assignmentTypeAnnotation+"."+dev.NetworkName]; ok {
+		return assignmentType(v)
+	}
+	if i == 0 {
+		if v, ok := annotations[assignmentTypeAnnotation]; ok {
+			return assignmentType(v)
+		}
+	}
+	if dev.DHCP4 {
+		return assignmentTypeDHCP
+	}
+	return assignmentTypeIPAM
+}
+
+// deviceBackendName resolves which registered ipam.Manager backend should
+// handle device i's allocation, keyed by network name and falling back to
+// the un-suffixed annotation for the first device. It returns "" when
+// neither is set, leaving IPAMBackends to apply its configured default.
+func deviceBackendName(annotations map[string]string, dev *v1alpha3.NetworkDeviceSpec, i int) string {
+	if v, ok := annotations[ipamBackendAnnotation+"."+dev.NetworkName]; ok {
+		return v
+	}
+	if i == 0 {
+		return annotations[ipamBackendAnnotation]
+	}
+	return ""
+}
+
+// deviceIdentifier returns the IPAM identifier for the device at index i.
+// The first device keeps the bare machine name for backwards compatibility
+// with existing single-NIC IPAM reservations; additional devices get an
+// index suffix so they don't collide with it or each other.
+func deviceIdentifier(machineName string, i int) string {
+	if i == 0 {
+		return machineName
+	}
+	return fmt.Sprintf("%s-%d", machineName, i)
+}
+
+// ensureIPAddressClaim returns the IPAddressClaim named identifier in
+// machine's namespace, creating it (owned by machine, so it's cleaned up
+// automatically once machine is deleted) if it doesn't exist yet. The
+// claim's own IPAddressClaimReconciler fulfills it asynchronously.
+func (r *VSphereMachineReconciler) ensureIPAddressClaim(ctx context.Context, machine *v1alpha3.VSphereMachine, identifier string, cfg deviceConfig, backend string) (*ipamv1alpha1.IPAddressClaim, error) {
+	claim := &ipamv1alpha1.IPAddressClaim{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: identifier}, claim)
+	if err == nil {
+		return claim, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	claim = &ipamv1alpha1.IPAddressClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: identifier, Namespace: machine.Namespace},
+		Spec: ipamv1alpha1.IPAddressClaimSpec{
+			Backend:     backend,
+			NetworkView: cfg.networkView,
+			Subnet:      cfg.subnet.String(),
+		},
+	}
+	if err := controllerutil.SetControllerReference(machine, claim, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// resolveDeviceConfig resolves the network view and subnet to allocate from
+// for a device. When the machine has a failure domain and a VSphereIPAMPool
+// in its namespace has a matching entry for the device's network, that entry
+// wins and the returned pool is used to record allocation utilization.
+// Otherwise it falls back to the current annotation-based behavior.
+func (r *VSphereMachineReconciler) resolveDeviceConfig(ctx context.Context, machine *v1alpha3.VSphereMachine, dev *v1alpha3.NetworkDeviceSpec, i int, annotations map[string]string, failureDomain string) (deviceConfig, *ipamv1alpha1.VSphereIPAMPool, error) {
+	if failureDomain != "" {
+		cfg, pool, found, err := r.failureDomainDeviceConfig(ctx, machine.Namespace, machine.Labels[clusterNameLabel], dev.NetworkName, failureDomain)
+		if err != nil {
+			return deviceConfig{}, nil, err
+		}
+		if found {
+			return cfg, pool, nil
+		}
+	}
+
+	cfg, err := deviceIPAMConfig(annotations, dev.NetworkName, i)
+	return cfg, nil, err
+}
+
+// failureDomainDeviceConfig looks for a VSphereIPAMPool in namespace, scoped
+// to clusterName, with an entry matching failureDomain and networkName.
+func (r *VSphereMachineReconciler) failureDomainDeviceConfig(ctx context.Context, namespace, clusterName, networkName, failureDomain string) (deviceConfig, *ipamv1alpha1.VSphereIPAMPool, bool, error) {
+	pools := &ipamv1alpha1.VSphereIPAMPoolList{}
+	if err := r.List(ctx, pools, client.InNamespace(namespace), client.MatchingLabels{clusterNameLabel: clusterName}); err != nil {
+		return deviceConfig{}, nil, false, err
+	}
+
+	for i := range pools.Items {
+		pool := &pools.Items[i]
+		for _, entry := range pool.Spec.FailureDomains {
+			if entry.Name != failureDomain || entry.NetworkName != networkName {
+				continue
+			}
+			_, subnet, err := net.ParseCIDR(entry.Subnet)
+			if err != nil {
+				return deviceConfig{}, nil, false, fmt.Errorf("parsing subnet of pool %s/%s failure domain %q: %w", pool.Namespace, pool.Name, entry.Name, err)
+			}
+			return deviceConfig{networkView: entry.NetworkView, subnet: subnet}, pool, true, nil
+		}
+	}
+
+	return deviceConfig{}, nil, false, nil
+}
+
+// maxPoolConflictRetries bounds how many times recordPoolUtilization retries
+// a VSphereIPAMPool status update after losing an optimistic-concurrency
+// race with another reconcile, mirroring kubenative.Manager.withPool.
+const maxPoolConflictRetries = 5
+
+// recordPoolUtilization adjusts the allocated-IP count the VSphereIPAMPool
+// named poolKey reports for failureDomain by delta (+1 on allocation, -1 on
+// release). Devices across different VSphereMachines can allocate from the
+// same pool concurrently, so on a conflict it refetches the pool and retries
+// the update from scratch, the same pattern kubenative.Manager.withPool uses
+// for IPPool.
+func (r *VSphereMachineReconciler) recordPoolUtilization(ctx context.Context, poolKey types.NamespacedName, failureDomain string, delta int) error {
+	for attempt := 0; attempt < maxPoolConflictRetries; attempt++ {
+		pool := &ipamv1alpha1.VSphereIPAMPool{}
+		if err := r.Get(ctx, poolKey, pool); err != nil {
+			return err
+		}
+
+		changed, err := applyPoolUtilization(pool, failureDomain, delta)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+
+		err = r.Status().Update(ctx, pool)
+		if err == nil {
+			return nil
+		}
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("VSphereIPAMPool %s: too many conflicting status updates", poolKey)
+}
+
+// applyPoolUtilization adjusts pool's in-memory status for failureDomain by
+// delta, reporting whether pool was modified (false when failureDomain has
+// no matching Spec entry, so there's nothing to persist).
+func applyPoolUtilization(pool *ipamv1alpha1.VSphereIPAMPool, failureDomain string, delta int) (bool, error) {
+	var entry *ipamv1alpha1.FailureDomainPool
+	for i := range pool.Spec.FailureDomains {
+		if pool.Spec.FailureDomains[i].Name == failureDomain {
+			entry = &pool.Spec.FailureDomains[i]
+			break
+		}
+	}
+	if entry == nil {
+		return false, nil
+	}
+
+	idx := -1
+	for i, s := range pool.Status.FailureDomains {
+		if s.Name == failureDomain {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		_, subnet, err := net.ParseCIDR(entry.Subnet)
+		if err != nil {
+			return false, err
+		}
+		ones, bits := subnet.Mask.Size()
+		pool.Status.FailureDomains = append(pool.Status.FailureDomains, ipamv1alpha1.FailureDomainPoolStatus{
+			Name:     failureDomain,
+			Subnet:   entry.Subnet,
+			TotalIPs: 1 << uint(bits-ones),
+		})
+		idx = len(pool.Status.FailureDomains) - 1
+	}
+
+	pool.Status.FailureDomains[idx].AllocatedIPs += delta
+	if pool.Status.FailureDomains[idx].AllocatedIPs < 0 {
+		pool.Status.FailureDomains[idx].AllocatedIPs = 0
+	}
+	return true, nil
+}
+
+// deviceIPAMConfig resolves the network view and subnet to allocate from for
+// the device at index i, keyed by network name.
+func deviceIPAMConfig(annotations map[string]string, networkName string, i int) (deviceConfig, error) {
+	subnetStr, ok := annotations[subnetAnnotation+"."+networkName]
+	if !ok && i == 0 {
+		subnetStr, ok = annotations[subnetAnnotation]
+	}
+	if !ok {
+		return deviceConfig{}, fmt.Errorf("no %q annotation for device %d (network %q)", subnetAnnotation, i, networkName)
+	}
+
+	_, subnet, err := net.ParseCIDR(subnetStr)
+	if err != nil {
+		return deviceConfig{}, fmt.Errorf("parsing %q annotation: %w", subnetAnnotation, err)
+	}
+
+	networkView := annotations[infobloxNetworkViewAnnotation+"."+networkName]
+	if networkView == "" && i == 0 {
+		networkView = annotations[infobloxNetworkViewAnnotation]
+	}
+
+	return deviceConfig{networkView: networkView, subnet: subnet}, nil
+}
+
+// annotationsFor returns the annotations to resolve IPAM configuration from:
+// the VSphereMachine's own annotations, or - if it doesn't carry the subnet
+// annotation itself - those of its owning Machine.
+func (r *VSphereMachineReconciler) annotationsFor(ctx context.Context, machine *v1alpha3.VSphereMachine) (map[string]string, error) {
+	if _, ok := machine.Annotations[subnetAnnotation]; ok {
+		return machine.Annotations, nil
+	}
+
+	owner, err := r.ownerMachine(ctx, machine)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return owner.Annotations, nil
+	}
+
+	return machine.Annotations, nil
+}
+
+// failureDomainFor returns the failure domain of machine's owning Machine,
+// or "" if it has none (or no owning Machine). VSphereMachine itself has no
+// FailureDomain field in this CAPV version, so the owner is the only source.
+func (r *VSphereMachineReconciler) failureDomainFor(ctx context.Context, machine *v1alpha3.VSphereMachine) (string, error) {
+	owner, err := r.ownerMachine(ctx, machine)
+	if err != nil {
+		return "", err
+	}
+	if owner == nil || owner.Spec.FailureDomain == nil {
+		return "", nil
+	}
+	return *owner.Spec.FailureDomain, nil
+}
+
+// ownerMachine returns the CAPI Machine owning machine, or nil if it has no
+// such owner reference.
+func (r *VSphereMachineReconciler) ownerMachine(ctx context.Context, machine *v1alpha3.VSphereMachine) (*capiv1alpha3.Machine, error) {
+	for _, ref := range machine.OwnerReferences {
+		if ref.Kind != "Machine" {
+			continue
+		}
+		owner := &capiv1alpha3.Machine{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: machine.Namespace, Name: ref.Name}, owner); err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}
+	return nil, nil
+}
+
+func (r *VSphereMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha3.VSphereMachine{}).
+		Owns(&ipamv1alpha1.IPAddressClaim{}).
+		Complete(r)
+}
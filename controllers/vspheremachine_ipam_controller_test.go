@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net"
 
 	. "github.com/onsi/ginkgo"
@@ -12,6 +13,8 @@ import (
 	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
 )
 
 var _ = Describe("VSphereMachine IPAM controller", func() {
@@ -208,8 +211,337 @@ var _ = Describe("VSphereMachine IPAM controller", func() {
 			Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
 		})
 	})
+
+	Context("when a machine has multiple network devices", func() {
+		const (
+			NetworkName2 = "testNetwork2"
+			NetworkView2 = "testview2"
+		)
+		var TestSubnet2 = net.IPNet{
+			IP:   net.IPv4(10, 0, 1, 0),
+			Mask: net.IPv4Mask(255, 255, 255, 0),
+		}
+
+		multiMeta := func() v1.ObjectMeta {
+			m := meta
+			m.Annotations = map[string]string{
+				networkNameAnnotation:                              NetworkName,
+				infobloxNetworkViewAnnotation:                      NetworkView,
+				subnetAnnotation:                                   TestSubnet.String(),
+				subnetAnnotation + "." + NetworkName2:              TestSubnet2.String(),
+				infobloxNetworkViewAnnotation + "." + NetworkName2: NetworkView2,
+				clusterNameLabel:                                   ClusterName,
+			}
+			return m
+		}
+
+		It("allocates and releases an IP for every device", func() {
+			ctx := context.Background()
+			allocatedFirst := false
+			allocatedSecond := false
+			ipamManager.Callback = func(t, id, networkView string, subnet *net.IPNet) {
+				if t != "GetOrAllocate" {
+					return
+				}
+				if id == MachineName && networkView == NetworkView && subnet.String() == TestSubnet.String() {
+					allocatedFirst = true
+				}
+				if id == MachineName+"-1" && networkView == NetworkView2 && subnet.String() == TestSubnet2.String() {
+					allocatedSecond = true
+				}
+			}
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: multiMeta(),
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network: v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{
+							{NetworkName: NetworkName},
+							{NetworkName: NetworkName2},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			createdMachine := &v1alpha3.VSphereMachine{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, NamespacedName, createdMachine)
+				if err != nil || len(createdMachine.Spec.Network.Devices) != 2 {
+					return false
+				}
+				return len(createdMachine.Spec.Network.Devices[0].IPAddrs) > 0 &&
+					len(createdMachine.Spec.Network.Devices[1].IPAddrs) > 0
+			}, timeout, interval).Should(BeTrue())
+			Expect(allocatedFirst).To(BeTrue(), "should allocate the ip for the first device")
+			Expect(allocatedSecond).To(BeTrue(), "should allocate the ip for the second device")
+
+			releasedFirst := false
+			releasedSecond := false
+			ipamManager.Callback = func(t, id, networkView string, subnet *net.IPNet) {
+				if t != "ReleaseIP" {
+					return
+				}
+				if id == MachineName && networkView == NetworkView && subnet.String() == TestSubnet.String() {
+					releasedFirst = true
+				}
+				if id == MachineName+"-1" && networkView == NetworkView2 && subnet.String() == TestSubnet2.String() {
+					releasedSecond = true
+				}
+			}
+			Expect(k8sClient.Delete(ctx, &v1alpha3.VSphereMachine{ObjectMeta: multiMeta()})).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, NamespacedName, &v1alpha3.VSphereMachine{})
+				return err != nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(releasedFirst).To(BeTrue(), "should release the ip for the first device")
+			Expect(releasedSecond).To(BeTrue(), "should release the ip for the second device")
+		})
+
+		It("keeps the IP allocated to an earlier device when a later device's claim fails to bind", func() {
+			// Each device now owns an independent IPAddressClaim, fulfilled
+			// by IPAddressClaimReconciler on its own schedule. A later
+			// device failing to bind no longer rolls back an earlier
+			// device's already-bound claim: that's the point of decoupling
+			// allocation from this controller's single reconcile loop.
+			ctx := context.Background()
+			ipamManager.FailAllocateFor = map[string]error{MachineName + "-1": errAllocate}
+
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: multiMeta(),
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network: v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{
+							{NetworkName: NetworkName},
+							{NetworkName: NetworkName2},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			createdMachine := &v1alpha3.VSphereMachine{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, NamespacedName, createdMachine); err != nil {
+					return false
+				}
+				return len(createdMachine.Spec.Network.Devices[0].IPAddrs) > 0
+			}, timeout, interval).Should(BeTrue(), "should keep the IP allocated to the first device")
+
+			Consistently(func() (int, error) {
+				if err := k8sClient.Get(ctx, NamespacedName, createdMachine); err != nil {
+					return -1, err
+				}
+				return len(createdMachine.Spec.Network.Devices[1].IPAddrs), nil
+			}, duration, interval).Should(Equal(0), "the second device stays unbound while its claim can't allocate")
+
+			By("binding the second device once the backend recovers")
+			ipamManager.FailAllocateFor = nil
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, NamespacedName, createdMachine); err != nil {
+					return false
+				}
+				return len(createdMachine.Spec.Network.Devices[1].IPAddrs) > 0
+			}, timeout, interval).Should(BeTrue(), "should bind the second device once its claim can allocate")
+		})
+	})
+
+	Context("when the owning Machine declares a failure domain", func() {
+		const (
+			FailureDomain   = "az-1"
+			PoolNetworkView = "pool-view"
+		)
+		var PoolSubnet = net.IPNet{
+			IP:   net.IPv4(10, 0, 2, 0),
+			Mask: net.IPv4Mask(255, 255, 255, 0),
+		}
+
+		It("allocates from the matching VSphereIPAMPool entry instead of the annotations", func() {
+			ctx := context.Background()
+			fd := FailureDomain
+			capiMachine := &capiv1alpha3.Machine{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      MachineName,
+					Namespace: Namespace,
+				},
+				Spec: capiv1alpha3.MachineSpec{
+					ClusterName:   ClusterName,
+					FailureDomain: &fd,
+				},
+			}
+			Expect(k8sClient.Create(ctx, capiMachine)).To(Succeed())
+
+			pool := &ipamv1alpha1.VSphereIPAMPool{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "pool",
+					Namespace: Namespace,
+					Labels:    map[string]string{clusterNameLabel: ClusterName},
+				},
+				Spec: ipamv1alpha1.VSphereIPAMPoolSpec{
+					FailureDomains: []ipamv1alpha1.FailureDomainPool{
+						{Name: FailureDomain, NetworkName: NetworkName, NetworkView: PoolNetworkView, Subnet: PoolSubnet.String()},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pool)).To(Succeed())
+
+			allocatedFromPool := false
+			ipamManager.Callback = func(t, id, networkView string, subnet *net.IPNet) {
+				if t == "GetOrAllocate" && id == MachineName && networkView == PoolNetworkView && subnet.String() == PoolSubnet.String() {
+					allocatedFromPool = true
+				}
+			}
+
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      MachineName,
+					Namespace: Namespace,
+					Labels:    map[string]string{clusterNameLabel: ClusterName},
+					OwnerReferences: []v1.OwnerReference{
+						{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Machine", Name: capiMachine.Name, UID: capiMachine.UID},
+					},
+				},
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network:  v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{{NetworkName: NetworkName}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			Eventually(func() bool {
+				return allocatedFromPool
+			}, timeout, interval).Should(BeTrue(), "should allocate from the failure domain pool entry")
+
+			Eventually(func() int {
+				updated := &ipamv1alpha1.VSphereIPAMPool{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: Namespace, Name: pool.Name}, updated); err != nil {
+					return -1
+				}
+				for _, s := range updated.Status.FailureDomains {
+					if s.Name == FailureDomain {
+						return s.AllocatedIPs
+					}
+				}
+				return 0
+			}, timeout, interval).Should(Equal(1), "should record the allocation in the pool's status")
+
+			Expect(k8sClient.Delete(ctx, capiMachine)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, pool)).To(Succeed())
+		})
+	})
+
+	Context("when a device declares an explicit assignment type", func() {
+		metaWithAssignmentType := func(t string) v1.ObjectMeta {
+			m := meta
+			m.Annotations = map[string]string{
+				networkNameAnnotation:         NetworkName,
+				infobloxNetworkViewAnnotation: NetworkView,
+				subnetAnnotation:              TestSubnet.String(),
+				assignmentTypeAnnotation:      t,
+				clusterNameLabel:              ClusterName,
+			}
+			return m
+		}
+
+		It("allocates via IPAM for assignment type \"ipam\"", func() {
+			ctx := context.Background()
+			allocated := false
+			ipamManager.Callback = func(t, id, networkView string, subnet *net.IPNet) {
+				if t == "GetOrAllocate" && id == MachineName {
+					allocated = true
+				}
+			}
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: metaWithAssignmentType(string(assignmentTypeIPAM)),
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network:  v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{{NetworkName: NetworkName}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			createdMachine := &v1alpha3.VSphereMachine{}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, NamespacedName, createdMachine)
+				return err == nil && checkNetworkDevices(createdMachine.Spec.Network.Devices)
+			}, timeout, interval).Should(BeTrue())
+			Expect(allocated).To(BeTrue())
+			Expect(createdMachine.Finalizers).To(ContainElement(finalizer))
+		})
+
+		It("doesn't call IPAM and records a condition for assignment type \"dhcp\"", func() {
+			ctx := context.Background()
+			called := false
+			ipamManager.Callback = func(t, _, _ string, _ *net.IPNet) {
+				called = true
+			}
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: metaWithAssignmentType(string(assignmentTypeDHCP)),
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network:  v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{{NetworkName: NetworkName}}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			createdMachine := &v1alpha3.VSphereMachine{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, NamespacedName, createdMachine); err != nil {
+					return false
+				}
+				for _, c := range createdMachine.Status.Conditions {
+					if c.Type == DHCPAuthoritativeCondition && c.Status == "True" {
+						return true
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue(), "should record the DHCPAuthoritative condition")
+			Expect(createdMachine.Finalizers).NotTo(ContainElement(finalizer))
+			Expect(called).To(BeFalse(), "should not call ipam")
+		})
+
+		It("skips IPAM and keeps the existing address for assignment type \"static\"", func() {
+			ctx := context.Background()
+			called := false
+			ipamManager.Callback = func(t, _, _ string, _ *net.IPNet) {
+				called = true
+			}
+			machine := &v1alpha3.VSphereMachine{
+				ObjectMeta: metaWithAssignmentType(string(assignmentTypeStatic)),
+				Spec: v1alpha3.VSphereMachineSpec{
+					VirtualMachineCloneSpec: v1alpha3.VirtualMachineCloneSpec{
+						Template: Template,
+						Network: v1alpha3.NetworkSpec{Devices: []v1alpha3.NetworkDeviceSpec{
+							{NetworkName: NetworkName, IPAddrs: []string{"10.0.0.42/24"}},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+			createdMachine := &v1alpha3.VSphereMachine{}
+			waitForObject(ctx, NamespacedName, createdMachine)
+			Consistently(func() ([]string, error) {
+				if err := k8sClient.Get(ctx, NamespacedName, createdMachine); err != nil {
+					return nil, err
+				}
+				return createdMachine.Spec.Network.Devices[0].IPAddrs, nil
+			}, duration, interval).Should(Equal([]string{"10.0.0.42/24"}))
+			Expect(createdMachine.Finalizers).NotTo(ContainElement(finalizer))
+			Expect(called).To(BeFalse(), "should not call ipam")
+		})
+	})
 })
 
+var errAllocate = errors.New("ipam backend unavailable")
+
 func waitForObject(ctx context.Context, key types.NamespacedName, obj client.Object) {
 	Eventually(func() bool {
 		err := k8sClient.Get(ctx, key, obj)
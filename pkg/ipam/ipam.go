@@ -0,0 +1,23 @@
+// Package ipam defines the interface controllers use to allocate and
+// release IP addresses from an IP address management backend, either an
+// external system or in-cluster IPPool resources.
+package ipam
+
+import "net"
+
+// DefaultBackend is the backend name assumed when a machine or cluster
+// doesn't specify one, keeping existing single-backend setups working
+// unchanged after a Registry is introduced.
+const DefaultBackend = "infoblox"
+
+// Manager allocates and releases IP addresses for cluster-managed machines
+// from an IPAM backend (e.g. Infoblox, or the kube-native IPPool backend).
+type Manager interface {
+	// GetOrAllocateIP returns the IP already reserved for identifier in
+	// networkView/subnet, allocating a new one if none exists yet.
+	GetOrAllocateIP(identifier, networkView string, subnet *net.IPNet) (net.IP, error)
+
+	// ReleaseIP releases the IP previously allocated to identifier in
+	// networkView/subnet back to the IPAM system.
+	ReleaseIP(identifier, networkView string, subnet *net.IPNet) error
+}
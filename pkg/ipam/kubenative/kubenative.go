@@ -0,0 +1,211 @@
+// Package kubenative implements an ipam.Manager backed by IPPool custom
+// resources, for clusters without an external IPAM system such as
+// Infoblox.
+package kubenative
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam"
+)
+
+// maxConflictRetries bounds how many times Manager retries an IPPool
+// status update after losing an optimistic-concurrency race with another
+// reconcile.
+const maxConflictRetries = 5
+
+// Manager is an ipam.Manager backed by IPPool resources: each pool owns a
+// CIDR and tracks its allocations in Status.Allocations. Unlike the
+// Infoblox backend, networkView is ignored - the IPPool to allocate from
+// is found by matching its Spec.CIDR against subnet within Namespace.
+type Manager struct {
+	Client    client.Client
+	Namespace string
+}
+
+var _ ipam.Manager = &Manager{}
+
+// GetOrAllocateIP returns the IP already reserved for identifier in
+// subnet's IPPool, allocating the next free one if none exists yet.
+func (m *Manager) GetOrAllocateIP(identifier, _ string, subnet *net.IPNet) (net.IP, error) {
+	ctx := context.Background()
+
+	var result net.IP
+	err := m.withPool(ctx, subnet, func(pool *ipamv1alpha1.IPPool) (bool, error) {
+		for _, a := range pool.Status.Allocations {
+			if a.Identifier == identifier {
+				result = net.ParseIP(a.Address)
+				return false, nil
+			}
+		}
+
+		ip, offset, err := nextFreeIP(pool, subnet)
+		if err != nil {
+			return false, err
+		}
+
+		pool.Status.Allocations = append(pool.Status.Allocations, ipamv1alpha1.IPPoolAllocation{
+			Identifier: identifier,
+			Address:    ip.String(),
+		})
+		pool.Status.NextOffset = offset + 1
+		result = ip
+		return true, nil
+	})
+	return result, err
+}
+
+// ReleaseIP removes the allocation entry keyed by identifier from subnet's
+// IPPool, if present.
+func (m *Manager) ReleaseIP(identifier, _ string, subnet *net.IPNet) error {
+	ctx := context.Background()
+
+	return m.withPool(ctx, subnet, func(pool *ipamv1alpha1.IPPool) (bool, error) {
+		for i, a := range pool.Status.Allocations {
+			if a.Identifier != identifier {
+				continue
+			}
+			pool.Status.Allocations = append(pool.Status.Allocations[:i], pool.Status.Allocations[i+1:]...)
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// withPool finds the IPPool for subnet and calls mutate with it, persisting
+// its Status if mutate reports a change. Status updates race with other
+// reconciles via optimistic concurrency on the IPPool's resourceVersion; on
+// conflict, withPool refetches the pool and retries mutate from scratch.
+func (m *Manager) withPool(ctx context.Context, subnet *net.IPNet, mutate func(pool *ipamv1alpha1.IPPool) (bool, error)) error {
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		pool, err := m.poolFor(ctx, subnet)
+		if err != nil {
+			return err
+		}
+
+		changed, err := mutate(pool)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+
+		err = m.Client.Status().Update(ctx, pool)
+		if err == nil {
+			return nil
+		}
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("IPPool for subnet %s: too many conflicting updates", subnet)
+}
+
+// poolFor returns the IPPool in m.Namespace whose Spec.CIDR matches subnet.
+func (m *Manager) poolFor(ctx context.Context, subnet *net.IPNet) (*ipamv1alpha1.IPPool, error) {
+	pools := &ipamv1alpha1.IPPoolList{}
+	if err := m.Client.List(ctx, pools, client.InNamespace(m.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range pools.Items {
+		if pools.Items[i].Spec.CIDR == subnet.String() {
+			return &pools.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no IPPool with CIDR %s in namespace %q", subnet, m.Namespace)
+}
+
+// nextFreeIP finds the next unallocated address in subnet, starting from
+// pool.Status.NextOffset and wrapping around, skipping the network and
+// broadcast addresses, anything in pool.Spec.Reserved, and the addresses
+// already recorded in pool.Status.Allocations. It returns the address and
+// the offset it was found at.
+//
+// Only the set of addresses in use is tracked, not a bitmap of the whole
+// subnet, so this stays cheap to store even for a /16 pool.
+func nextFreeIP(pool *ipamv1alpha1.IPPool, subnet *net.IPNet) (net.IP, int64, error) {
+	ones, bits := subnet.Mask.Size()
+	size := int64(1) << uint(bits-ones)
+	if size < 3 {
+		return nil, 0, fmt.Errorf("subnet %s has no usable addresses", subnet)
+	}
+
+	used := make(map[string]struct{}, len(pool.Status.Allocations))
+	for _, a := range pool.Status.Allocations {
+		used[a.Address] = struct{}{}
+	}
+
+	start := pool.Status.NextOffset % size
+	for i := int64(0); i < size; i++ {
+		offset := (start + i) % size
+		if offset == 0 || offset == size-1 {
+			// network and broadcast addresses are never handed out.
+			continue
+		}
+
+		ip := offsetIP(subnet.IP, offset)
+		if _, ok := used[ip.String()]; ok {
+			continue
+		}
+
+		reserved, err := isReserved(ip, pool.Spec.Reserved)
+		if err != nil {
+			return nil, 0, err
+		}
+		if reserved {
+			continue
+		}
+
+		return ip, offset, nil
+	}
+
+	return nil, 0, fmt.Errorf("subnet %s is exhausted", subnet)
+}
+
+// isReserved reports whether ip falls within one of the entries in
+// reserved, each of which is either a single IP or a CIDR.
+func isReserved(ip net.IP, reserved []string) (bool, error) {
+	for _, r := range reserved {
+		if _, rnet, err := net.ParseCIDR(r); err == nil {
+			if rnet.Contains(ip) {
+				return true, nil
+			}
+			continue
+		}
+		if rip := net.ParseIP(r); rip != nil {
+			if rip.Equal(ip) {
+				return true, nil
+			}
+			continue
+		}
+		return false, fmt.Errorf("invalid reserved entry %q", r)
+	}
+	return false, nil
+}
+
+// offsetIP returns the address offset past base within its subnet.
+func offsetIP(base net.IP, offset int64) net.IP {
+	if v4 := base.To4(); v4 != nil {
+		v := new(big.Int).SetBytes(v4)
+		v.Add(v, big.NewInt(offset))
+		b := v.Bytes()
+		out := make(net.IP, net.IPv4len)
+		copy(out[net.IPv4len-len(b):], b)
+		return out
+	}
+
+	v := new(big.Int).SetBytes(base.To16())
+	v.Add(v, big.NewInt(offset))
+	b := v.Bytes()
+	out := make(net.IP, net.IPv6len)
+	copy(out[net.IPv6len-len(b):], b)
+	return out
+}
@@ -0,0 +1,134 @@
+package kubenative
+
+import (
+	"net"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ipamv1alpha1 "gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/api/v1alpha1"
+)
+
+func newTestManager(t *testing.T, pool *ipamv1alpha1.IPPool) (*Manager, *ipamv1alpha1.IPPool) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := ipamv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool).Build()
+	return &Manager{Client: c, Namespace: pool.Namespace}, pool
+}
+
+func testPool(cidr string, reserved ...string) *ipamv1alpha1.IPPool {
+	return &ipamv1alpha1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       ipamv1alpha1.IPPoolSpec{CIDR: cidr, Reserved: reserved},
+	}
+}
+
+func TestGetOrAllocateIP(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := newTestManager(t, testPool("10.0.0.0/30"))
+
+	ip, err := m.GetOrAllocateIP("machine-a", "", subnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ip.String() != "10.0.0.1" {
+		t.Fatalf("got %s, want 10.0.0.1 (the first usable address in a /30)", ip)
+	}
+
+	again, err := m.GetOrAllocateIP("machine-a", "", subnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !again.Equal(ip) {
+		t.Fatalf("got %s on repeat allocation, want the same address %s back", again, ip)
+	}
+
+	second, err := m.GetOrAllocateIP("machine-b", "", subnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.String() != "10.0.0.2" {
+		t.Fatalf("got %s, want 10.0.0.2 (the only other usable address in a /30)", second)
+	}
+
+	if _, err := m.GetOrAllocateIP("machine-c", "", subnet); err == nil {
+		t.Fatal("expected an error allocating from an exhausted /30")
+	}
+}
+
+func TestGetOrAllocateIP_SkipsReserved(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := newTestManager(t, testPool("10.0.0.0/29", "10.0.0.1"))
+
+	ip, err := m.GetOrAllocateIP("machine-a", "", subnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ip.String() != "10.0.0.2" {
+		t.Fatalf("got %s, want 10.0.0.2 (10.0.0.1 is reserved)", ip)
+	}
+}
+
+func TestReleaseIP(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := newTestManager(t, testPool("10.0.0.0/30"))
+
+	// Exhaust the /30 (two usable addresses), then release one back.
+	if _, err := m.GetOrAllocateIP("machine-a", "", subnet); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := m.GetOrAllocateIP("machine-b", "", subnet); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.ReleaseIP("machine-a", "", subnet); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ip, err := m.GetOrAllocateIP("machine-c", "", subnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ip.String() != "10.0.0.1" {
+		t.Fatalf("got %s, want 10.0.0.1 to be handed out again after release", ip)
+	}
+}
+
+func TestGetOrAllocateIP_NoMatchingPool(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := net.ParseCIDR("10.0.1.0/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := newTestManager(t, testPool(subnet.String()))
+
+	if _, err := m.GetOrAllocateIP("machine-a", "", other); err == nil {
+		t.Fatal("expected an error for a subnet with no matching IPPool")
+	}
+}
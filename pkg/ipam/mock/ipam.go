@@ -8,6 +8,10 @@ import (
 
 type Manager struct {
 	Callback func(t, identifier, networkView string, subnet *net.IPNet)
+
+	// FailAllocateFor, when non-nil, is consulted by GetOrAllocateIP and lets
+	// tests simulate an IPAM backend failure for a specific identifier.
+	FailAllocateFor map[string]error
 }
 
 var _ ipam.Manager = &Manager{}
@@ -20,6 +24,9 @@ func (m *Manager) GetOrAllocateIP(identifier, networkView string, subnet *net.IP
 	if m.Callback != nil {
 		m.Callback("GetOrAllocate", identifier, networkView, subnet)
 	}
+	if err := m.FailAllocateFor[identifier]; err != nil {
+		return nil, err
+	}
 	return net.IPv4(10, 0, 0, 0), nil
 }
 
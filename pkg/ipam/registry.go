@@ -0,0 +1,32 @@
+package ipam
+
+import "fmt"
+
+// Registry resolves a Manager by backend name, so a single controller can
+// support more than one IPAM backend (e.g. Infoblox and a kube-native
+// IPPool) at once and let callers pick between them per-cluster or
+// per-machine.
+type Registry struct {
+	backends map[string]Manager
+	def      string
+}
+
+// NewRegistry returns a Registry serving backends, resolving requests for
+// an empty backend name to the one registered as def.
+func NewRegistry(backends map[string]Manager, def string) *Registry {
+	return &Registry{backends: backends, def: def}
+}
+
+// Get returns the Manager registered under name, falling back to the
+// registry's default backend when name is empty. It returns an error if the
+// resolved name has no registered backend.
+func (r *Registry) Get(name string) (Manager, error) {
+	if name == "" {
+		name = r.def
+	}
+	m, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("no IPAM backend registered for %q", name)
+	}
+	return m, nil
+}
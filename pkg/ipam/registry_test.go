@@ -0,0 +1,48 @@
+package ipam_test
+
+import (
+	"net"
+	"testing"
+
+	"gitlab.devops.telekom.de/schiff/engine/schiff-operator.git/pkg/ipam"
+)
+
+type stubManager struct{ name string }
+
+func (s *stubManager) GetOrAllocateIP(string, string, *net.IPNet) (net.IP, error) { return nil, nil }
+func (s *stubManager) ReleaseIP(string, string, *net.IPNet) error                 { return nil }
+
+func TestRegistryGet(t *testing.T) {
+	infoblox := &stubManager{name: "infoblox"}
+	kubenative := &stubManager{name: "kubenative"}
+	registry := ipam.NewRegistry(map[string]ipam.Manager{
+		"infoblox":   infoblox,
+		"kubenative": kubenative,
+	}, "infoblox")
+
+	t.Run("empty name resolves to the default backend", func(t *testing.T) {
+		m, err := registry.Get("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m != infoblox {
+			t.Fatalf("got %v, want default backend", m)
+		}
+	})
+
+	t.Run("named backend resolves to itself", func(t *testing.T) {
+		m, err := registry.Get("kubenative")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if m != kubenative {
+			t.Fatalf("got %v, want kubenative backend", m)
+		}
+	})
+
+	t.Run("unknown backend errors", func(t *testing.T) {
+		if _, err := registry.Get("nope"); err == nil {
+			t.Fatal("expected an error for an unregistered backend")
+		}
+	})
+}